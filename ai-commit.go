@@ -2,55 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"google.golang.org/genai"
 	"log"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
 	"time"
-)
 
-const promptTemplate = `You are an AI assistant specialized in generating concise, single-line Conventional Commit messages from git diffs.
-Your **sole task** is to produce a commit message.
-The **primary and strongly preferred output** is a single line adhering to this exact format:
-<emoji> <type>(<scope>): <short description>
-e.g., 🐛 fix(parser): Correct off-by-one error in tokenization
-
-**Body and Footer (AVOID unless absolutely CRITICAL):**
-*   Only include a body or footer if the changes are exceptionally complex AND a single subject line is **demonstrably insufficient** to convey a **vital aspect** (e.g., a significant BREAKING CHANGE that cannot be summarized or hinted at, or an essential issue link).
-*   **Your default behavior must be to summarize everything into the single subject line.**
-*   If unavoidable, separate the body/footer with blank lines as per the specification.
-
-Available types and their emojis (choose one for the subject line):
-- feat: ✨ (A new feature)
-- fix: 🐛 (A bug fix)
-- docs: 📚 (Documentation only changes)
-- style: 💎 (Changes that do not affect the meaning of the code)
-- refactor: ♻️ (Code change that neither fixes a bug nor adds a feature)
-- perf: ⚡️ (Code change that improves performance)
-- test: ✅ (Adding or correcting tests)
-- build: 📦 (Changes to build system or external dependencies)
-- ci: ⚙️ (Changes to CI configuration)
-- chore: 🧹 (Other changes not modifying src or test files)
-- revert: ⏪ (Reverts a previous commit)
-
-Guidelines for the **single subject line**:
-1.  **Summarize the Core Change**: Identify the primary purpose/goal of the entire diff.
-2.  **Imperative Mood**: Start with a verb (e.g., 'Add', 'Fix', 'Update', 'Refactor').
-3.  **Conciseness**: Aim for 50-72 characters. Be brief but informative.
-4.  **No Period**: Do not end the subject line with a period.
-5.  **Scope (Optional)**: If applicable, a noun describing the affected area (e.g., 'api', 'ui', 'auth').
-6.  **Emoji & Type**: Select the most fitting type and its emoji.
-7.  **Focus**: Prioritize the overall *intent* and *impact*, not granular file-by-file details. Distill the essence of the changes.%s
-Here is the git diff of the changes:
-\\\ diff
-%s
-\\\ diff
-Based ONLY on the diff provided, generate the commit message.
-**Your response should be ONLY the commit message itself, with NO additional text, explanation, or markdown formatting surrounding it.**
-**Strive for a single line. Every time.**`
+	"github.com/manifoldco/promptui"
+)
 
 func showSpinner(ctx context.Context, message string) {
 	spinnerChars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
@@ -72,24 +35,121 @@ func showSpinner(ctx context.Context, message string) {
 	}
 }
 
-const geminiModel = "gemini-2.5-flash-preview-05-20"
+// pickCandidateIndexed shows an arrow-key selectable list of candidates and
+// returns the index and text chosen by the user. With a single candidate it
+// is returned as-is.
+func pickCandidateIndexed(candidates []string) (int, string, error) {
+	if len(candidates) == 1 {
+		return 0, candidates[0], nil
+	}
 
-func main() {
-	geminiApiKey := os.Getenv("GEMINI_API_KEY")
+	prompt := promptui.Select{
+		Label: "Select a commit message",
+		Items: candidates,
+		Size:  len(candidates),
+	}
 
-	if geminiApiKey == "" {
-		log.Fatal("GEMINI_API_KEY environment variable is not set.")
+	index, selected, err := prompt.Run()
+	if err != nil {
+		return 0, "", fmt.Errorf("selection cancelled: %w", err)
 	}
+	return index, selected, nil
+}
 
-	additionalContext := ""
-	if len(os.Args) > 1 {
-		input := strings.Join(os.Args[1:], " ")
-		additionalContext = "\n" +
-			fmt.Sprintf(`User-provided hint/context for this commit: %s
-Please take this hint into account when generating the commit message.
-`, input)
+// editInEditor opens the user's $EDITOR (falling back to vi) on a temp file
+// seeded with initial, then returns the edited contents.
+func editInEditor(initial string) (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	tmpFile, err := os.CreateTemp("", "ai-commit-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(initial); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", fmt.Errorf("closing temp file: %w", err)
+	}
+
+	cmd := exec.Command(editor, tmpFile.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("reading edited message: %w", err)
+	}
+	return strings.TrimSpace(string(edited)), nil
+}
+
+// commitWithMessage runs `git commit -m message` against the current repo.
+func commitWithMessage(message string) error {
+	cmd := exec.Command("git", "commit", "-m", message)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// genOptions bundles the diff-chunking and validation knobs shared by the
+// interactive flow and hook mode.
+type genOptions struct {
+	strategy   string
+	maxTokens  int
+	parallel   int
+	maxRetries int
+}
+
+// buildPromptForStaged fetches the currently staged diff and turns it into a
+// finished prompt, chunking the diff first via map-reduce when it's large.
+func buildPromptForStaged(ctx context.Context, provider Provider, cfg Config, hint string, opts genOptions) (string, error) {
+	stagedDiff, err := exec.Command("git", "diff", "--staged", "--patch", "--unified=5").Output()
+	if err != nil {
+		return "", fmt.Errorf("getting staged diff: %w", err)
+	}
+	if len(stagedDiff) == 0 {
+		return "", fmt.Errorf("no staged files to commit")
+	}
+
+	diffForPrompt := string(stagedDiff)
+	if resolveStrategy(opts.strategy, diffForPrompt, opts.maxTokens) == strategyMapReduce {
+		diffForPrompt, err = mapReduceSummary(ctx, provider, diffForPrompt, opts.maxTokens, opts.parallel)
+		if err != nil {
+			return "", fmt.Errorf("summarizing large diff: %w", err)
+		}
+	}
+
+	return buildPrompt(cfg, hint, diffForPrompt)
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "install-hook" {
+		if err := installHook(); err != nil {
+			log.Fatalf("Error installing hook: %v", err)
+		}
+		return
 	}
 
+	count := flag.Int("count", 1, "number of candidate commit messages to request")
+	noCommit := flag.Bool("no-commit", false, "print the selected message instead of editing and committing it")
+	maxTokens := flag.Int("max-tokens", 12000, "token budget (4 chars/token heuristic) before chunking the diff")
+	parallelism := flag.Int("parallel", 4, "max concurrent chunk-summarization requests")
+	strategy := flag.String("strategy", strategyAuto, "diff summarization strategy: single, map-reduce, or auto")
+	jsonOutput := flag.Bool("json", false, "print the selected message's validated JSON instead of a formatted message")
+	maxRetries := flag.Int("max-retries", 2, "retries for a generated message that fails schema validation")
+	hookMode := flag.Bool("hook", false, "run as a prepare-commit-msg hook (see install-hook)")
+	flag.Parse()
+
 	insideGitRepoOutput, err := exec.Command("git", "rev-parse", "--is-inside-work-tree").Output()
 	if err != nil {
 		log.Fatalf("Error checking git repository status: %v", err)
@@ -98,30 +158,47 @@ Please take this hint into account when generating the commit message.
 		log.Fatal("Not inside a git repository.")
 	}
 
-	stagedDiff, err := exec.Command("git", "diff", "--staged", "--patch", "--unified=5").Output()
+	cfg, err := loadConfig()
 	if err != nil {
-		log.Fatalf("Error getting staged diff: %v", err)
+		if *hookMode {
+			fmt.Fprintf(os.Stderr, "ai-commit: skipping suggestion: loading config: %v\n", err)
+			return
+		}
+		log.Fatalf("Error loading config: %v", err)
 	}
-	if len(stagedDiff) == 0 {
-		log.Fatal("No staged files to commit.")
+
+	ctx := context.Background()
+	provider, err := newProvider(ctx)
+	if err != nil {
+		if *hookMode {
+			fmt.Fprintf(os.Stderr, "ai-commit: skipping suggestion: setting up LLM provider: %v\n", err)
+			return
+		}
+		log.Fatalf("Error setting up LLM provider: %v", err)
 	}
 
-	promptText := fmt.Sprintf(promptTemplate, additionalContext, string(stagedDiff))
+	opts := genOptions{strategy: *strategy, maxTokens: *maxTokens, parallel: *parallelism, maxRetries: *maxRetries}
 
-	clientAPICtx := context.Background()
-	client, err := genai.NewClient(clientAPICtx, &genai.ClientConfig{
-		APIKey:  geminiApiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	if *hookMode {
+		if err := runHook(ctx, provider, cfg, flag.Args(), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "ai-commit: skipping suggestion: %v\n", err)
+		}
+		return
+	}
 
-	if err != nil {
-		log.Fatalf("Error creating client: %v", err)
+	additionalContext := ""
+	if flag.NArg() > 0 {
+		input := strings.Join(flag.Args(), " ")
+		additionalContext = "\n" +
+			fmt.Sprintf(`User-provided hint/context for this commit: %s
+Please take this hint into account when generating the commit message.
+`, input)
 	}
 
 	spinnerCtx, cancelSpinner := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
 
-	spinnerMessage := fmt.Sprintf("🤖 %s", geminiModel)
+	spinnerMessage := fmt.Sprintf("🤖 %s", provider.Model())
 
 	wg.Add(1)
 	go func() {
@@ -129,12 +206,14 @@ Please take this hint into account when generating the commit message.
 		showSpinner(spinnerCtx, spinnerMessage)
 	}()
 
-	result, err := client.Models.GenerateContent(
-		clientAPICtx,
-		geminiModel,
-		genai.Text(promptText),
-		nil,
-	)
+	promptText, err := buildPromptForStaged(ctx, provider, cfg, additionalContext, opts)
+	if err != nil {
+		cancelSpinner()
+		wg.Wait()
+		log.Fatalf("Error building prompt: %v", err)
+	}
+
+	messages, err := generateStructuredCandidates(ctx, provider, cfg, promptText, *count, *maxRetries)
 
 	cancelSpinner()
 	wg.Wait()
@@ -143,5 +222,39 @@ Please take this hint into account when generating the commit message.
 		log.Fatalf("Error generating commit message : %v", err)
 	}
 
-	fmt.Println(result.Text())
+	candidates := make([]string, len(messages))
+	for i, m := range messages {
+		candidates[i] = m.Format(cfg)
+	}
+
+	pickedIndex, selected, err := pickCandidateIndexed(candidates)
+	if err != nil {
+		log.Fatalf("Error selecting commit message: %v", err)
+	}
+
+	if *jsonOutput {
+		raw, err := json.MarshalIndent(messages[pickedIndex], "", "  ")
+		if err != nil {
+			log.Fatalf("Error marshaling commit message: %v", err)
+		}
+		fmt.Println(string(raw))
+		return
+	}
+
+	if *noCommit {
+		fmt.Println(selected)
+		return
+	}
+
+	final, err := editInEditor(selected)
+	if err != nil {
+		log.Fatalf("Error editing commit message: %v", err)
+	}
+	if final == "" {
+		log.Fatal("Empty commit message, aborting.")
+	}
+
+	if err := commitWithMessage(final); err != nil {
+		log.Fatalf("Error committing: %v", err)
+	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+type anthropicProvider struct {
+	client *anthropic.Client
+	model  string
+}
+
+func newAnthropicProvider(model string) (Provider, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is not set")
+	}
+
+	client := anthropic.NewClient(option.WithAPIKey(apiKey))
+	return &anthropicProvider{client: &client, model: model}, nil
+}
+
+func (p *anthropicProvider) Model() string {
+	return p.model
+}
+
+func (p *anthropicProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	return genericGenerateJSON(ctx, p, prompt, count)
+}
+
+// Generate issues count sequential requests: the Messages API has no notion
+// of multiple candidates per call.
+func (p *anthropicProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	candidates := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		msg, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+			Model:     anthropic.F(p.model),
+			MaxTokens: anthropic.F(int64(256)),
+			Messages: anthropic.F([]anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+			}),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(msg.Content) == 0 {
+			continue
+		}
+		text := strings.TrimSpace(msg.Content[0].Text)
+		if text != "" {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return candidates, nil
+}
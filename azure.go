@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+type azureOpenAIProvider struct {
+	client     *azopenai.Client
+	deployment string
+}
+
+func newAzureOpenAIProvider(deployment string) (Provider, error) {
+	endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("AZURE_OPENAI_ENDPOINT environment variable is not set")
+	}
+	apiKey := os.Getenv("AZURE_OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("AZURE_OPENAI_API_KEY environment variable is not set")
+	}
+
+	client, err := azopenai.NewClientWithKeyCredential(endpoint, azcore.NewKeyCredential(apiKey), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating Azure OpenAI client: %w", err)
+	}
+
+	return &azureOpenAIProvider{client: client, deployment: deployment}, nil
+}
+
+func (p *azureOpenAIProvider) Model() string {
+	return p.deployment
+}
+
+func (p *azureOpenAIProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	return genericGenerateJSON(ctx, p, prompt, count)
+}
+
+func (p *azureOpenAIProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	resp, err := p.client.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
+		DeploymentName: &p.deployment,
+		Messages: []azopenai.ChatRequestMessageClassification{
+			&azopenai.ChatRequestUserMessage{Content: azopenai.NewChatRequestUserMessageContent(prompt)},
+		},
+		N: to.Ptr(int32(count)),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0, len(resp.Choices))
+	for _, choice := range resp.Choices {
+		if choice.Message == nil || choice.Message.Content == nil {
+			continue
+		}
+		text := strings.TrimSpace(*choice.Message.Content)
+		if text != "" {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return candidates, nil
+}
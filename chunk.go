@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	strategySingle    = "single"
+	strategyMapReduce = "map-reduce"
+	strategyAuto      = "auto"
+)
+
+// estimateTokens is a cheap 4-chars/token heuristic, good enough to decide
+// whether a diff needs chunking.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+var fileDiffHeader = regexp.MustCompile(`(?m)^diff --git `)
+var hunkHeader = regexp.MustCompile(`(?m)^@@ `)
+
+// fileChunk is one file's diff, further split into hunk-sized pieces when it
+// alone exceeds the token budget.
+type fileChunk struct {
+	path   string
+	chunks []string
+}
+
+// splitDiffByFile breaks a unified diff on "diff --git" boundaries, one
+// entry per touched file.
+func splitDiffByFile(diff string) []string {
+	idxs := fileDiffHeader.FindAllStringIndex(diff, -1)
+	if len(idxs) == 0 {
+		return []string{diff}
+	}
+
+	files := make([]string, 0, len(idxs))
+	for i, idx := range idxs {
+		end := len(diff)
+		if i+1 < len(idxs) {
+			end = idxs[i+1][0]
+		}
+		files = append(files, diff[idx[0]:end])
+	}
+	return files
+}
+
+// filePath extracts the "b/" path from a single file diff's header line.
+func filePath(fileDiff string) string {
+	firstLine := fileDiff
+	if nl := strings.IndexByte(fileDiff, '\n'); nl != -1 {
+		firstLine = fileDiff[:nl]
+	}
+	fields := strings.Fields(firstLine)
+	if len(fields) >= 4 {
+		return strings.TrimPrefix(fields[3], "b/")
+	}
+	return firstLine
+}
+
+// splitHunks further breaks a single file's diff on "@@" hunk boundaries when
+// it exceeds maxTokens, keeping the file header attached to the first hunk.
+func splitHunks(fileDiff string, maxTokens int) []string {
+	if estimateTokens(fileDiff) <= maxTokens {
+		return []string{fileDiff}
+	}
+
+	idxs := hunkHeader.FindAllStringIndex(fileDiff, -1)
+	if len(idxs) <= 1 {
+		return []string{fileDiff}
+	}
+
+	header := fileDiff[:idxs[0][0]]
+	chunks := make([]string, 0, len(idxs))
+	for i, idx := range idxs {
+		end := len(fileDiff)
+		if i+1 < len(idxs) {
+			end = idxs[i+1][0]
+		}
+		chunk := fileDiff[idx[0]:end]
+		if i == 0 {
+			chunk = header + chunk
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// buildChunks splits diff into per-file pieces, splitting a file over
+// maxTokens into per-hunk pieces; a single hunk over maxTokens stays whole.
+func buildChunks(diff string, maxTokens int) []fileChunk {
+	files := splitDiffByFile(diff)
+	result := make([]fileChunk, 0, len(files))
+	for _, f := range files {
+		result = append(result, fileChunk{
+			path:   filePath(f),
+			chunks: splitHunks(f, maxTokens),
+		})
+	}
+	return result
+}
+
+const summarizeChunkPrompt = `Summarize, in one or two plain sentences, what changed in this fragment of a file's diff. Do not speculate beyond the diff. No markdown, no preamble.
+
+%s`
+
+// summarizeChunks runs one "summarize this fragment" completion per chunk
+// across a bounded worker pool of size parallel. It returns one slice of
+// summaries per file, in the same order and with the same length as each
+// fileChunk's chunks, so a file split into several hunks keeps all of its
+// summaries rather than colliding with another file's.
+func summarizeChunks(ctx context.Context, provider Provider, chunks []fileChunk, parallel int) ([][]string, error) {
+	type job struct {
+		index      int
+		fileIndex  int
+		chunkIndex int
+		text       string
+	}
+
+	var jobs []job
+	for fi, fc := range chunks {
+		for ci, c := range fc.chunks {
+			jobs = append(jobs, job{
+				index:      len(jobs),
+				fileIndex:  fi,
+				chunkIndex: ci,
+				text:       fmt.Sprintf("File: %s\n\n%s", fc.path, c),
+			})
+		}
+	}
+
+	flat := make([]string, len(jobs))
+	errs := make([]error, len(jobs))
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results, err := provider.Generate(ctx, fmt.Sprintf(summarizeChunkPrompt, j.text), 1)
+			if err != nil {
+				errs[j.index] = err
+				return
+			}
+			flat[j.index] = strings.TrimSpace(results[0])
+		}(j)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("summarizing diff chunk: %w", err)
+		}
+	}
+
+	perFile := make([][]string, len(chunks))
+	for i, fc := range chunks {
+		perFile[i] = make([]string, len(fc.chunks))
+	}
+	for _, j := range jobs {
+		perFile[j.fileIndex][j.chunkIndex] = flat[j.index]
+	}
+	return perFile, nil
+}
+
+// diffStat runs `git diff --staged --stat` for the reducer prompt.
+func diffStat() (string, error) {
+	out, err := exec.Command("git", "diff", "--staged", "--stat").Output()
+	if err != nil {
+		return "", fmt.Errorf("getting diff stat: %w", err)
+	}
+	return string(out), nil
+}
+
+// mapReduceSummary chunks diff, summarizes each chunk in parallel, and folds
+// the summaries plus file stats into the text that replaces the raw diff in
+// the final prompt.
+func mapReduceSummary(ctx context.Context, provider Provider, diff string, maxTokens, parallel int) (string, error) {
+	chunks := buildChunks(diff, maxTokens)
+
+	summaries, err := summarizeChunks(ctx, provider, chunks, parallel)
+	if err != nil {
+		return "", err
+	}
+
+	stat, err := diffStat()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("File-by-file summary (raw diff omitted, too large):\n")
+	for i, fc := range chunks {
+		fmt.Fprintf(&b, "- %s: %s\n", fc.path, strings.Join(summaries[i], " "))
+	}
+	b.WriteString("\nDiffstat:\n")
+	b.WriteString(stat)
+
+	return b.String(), nil
+}
+
+// resolveStrategy turns "auto" into a concrete single/map-reduce choice
+// based on the diff's estimated token count against maxTokens.
+func resolveStrategy(strategy, diff string, maxTokens int) string {
+	if strategy != strategyAuto {
+		return strategy
+	}
+	if estimateTokens(diff) > maxTokens {
+		return strategyMapReduce
+	}
+	return strategySingle
+}
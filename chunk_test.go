@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeProvider echoes back a deterministic, per-call summary so tests can
+// assert which chunk produced which summary without hitting a real backend.
+type fakeProvider struct {
+	calls int
+}
+
+func (p *fakeProvider) Model() string { return "fake" }
+
+func (p *fakeProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	p.calls++
+	return []string{fmt.Sprintf("summary-of[%s]", strings.TrimSpace(prompt))}, nil
+}
+
+func (p *fakeProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	return genericGenerateJSON(ctx, p, prompt, count)
+}
+
+func TestSplitDiffByFile(t *testing.T) {
+	diff := "diff --git a/one.go b/one.go\n@@ -1,1 +1,1 @@\n-old\n+new\n" +
+		"diff --git a/two.go b/two.go\n@@ -1,1 +1,1 @@\n-old2\n+new2\n"
+
+	files := splitDiffByFile(diff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if !strings.HasPrefix(files[0], "diff --git a/one.go") {
+		t.Errorf("files[0] = %q, want to start with one.go header", files[0])
+	}
+	if !strings.HasPrefix(files[1], "diff --git a/two.go") {
+		t.Errorf("files[1] = %q, want to start with two.go header", files[1])
+	}
+}
+
+func TestSplitHunksBelowBudgetStaysWhole(t *testing.T) {
+	fileDiff := "diff --git a/one.go b/one.go\n@@ -1,1 +1,1 @@\n-old\n+new\n"
+	chunks := splitHunks(fileDiff, 1_000_000)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1 (under budget)", len(chunks))
+	}
+}
+
+func TestSplitHunksAboveBudgetSplitsPerHunk(t *testing.T) {
+	fileDiff := "diff --git a/one.go b/one.go\n" +
+		"@@ -1,1 +1,1 @@\n-old1\n+new1\n" +
+		"@@ -10,1 +10,1 @@\n-old2\n+new2\n"
+
+	chunks := splitHunks(fileDiff, 1)
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2 (one per hunk)", len(chunks))
+	}
+	if !strings.Contains(chunks[0], "diff --git") {
+		t.Errorf("first chunk should keep the file header, got %q", chunks[0])
+	}
+	if !strings.Contains(chunks[0], "old1") || strings.Contains(chunks[0], "old2") {
+		t.Errorf("first chunk should contain only the first hunk, got %q", chunks[0])
+	}
+	if !strings.Contains(chunks[1], "old2") || strings.Contains(chunks[1], "old1") {
+		t.Errorf("second chunk should contain only the second hunk, got %q", chunks[1])
+	}
+}
+
+// TestSummarizeChunksKeepsPerFileAlignment is the regression test for the
+// bug where a multi-hunk file shifted every subsequent file's summary by
+// one: here "multi.go" produces 2 hunk chunks and "other.go" produces 1, so
+// a naive per-file index into the flattened job list would mislabel
+// "other.go" with one of "multi.go"'s hunk summaries.
+func TestSummarizeChunksKeepsPerFileAlignment(t *testing.T) {
+	chunks := []fileChunk{
+		{path: "multi.go", chunks: []string{"hunk-A", "hunk-B"}},
+		{path: "other.go", chunks: []string{"hunk-C"}},
+	}
+
+	provider := &fakeProvider{}
+	summaries, err := summarizeChunks(context.Background(), provider, chunks, 4)
+	if err != nil {
+		t.Fatalf("summarizeChunks returned error: %v", err)
+	}
+
+	if len(summaries) != 2 {
+		t.Fatalf("got %d per-file summary slices, want 2", len(summaries))
+	}
+	if len(summaries[0]) != 2 {
+		t.Fatalf("multi.go: got %d summaries, want 2", len(summaries[0]))
+	}
+	if len(summaries[1]) != 1 {
+		t.Fatalf("other.go: got %d summaries, want 1", len(summaries[1]))
+	}
+
+	if !strings.Contains(summaries[0][0], "hunk-A") || !strings.Contains(summaries[0][1], "hunk-B") {
+		t.Errorf("multi.go summaries out of order or mislabeled: %v", summaries[0])
+	}
+	if !strings.Contains(summaries[1][0], "hunk-C") {
+		t.Errorf("other.go summary mislabeled, got %v, want to reference hunk-C", summaries[1])
+	}
+	if strings.Contains(summaries[1][0], "hunk-A") || strings.Contains(summaries[1][0], "hunk-B") {
+		t.Errorf("other.go summary leaked multi.go content: %v", summaries[1])
+	}
+}
+
+func TestSummarizeChunksClampsNonPositiveParallel(t *testing.T) {
+	chunks := []fileChunk{{path: "one.go", chunks: []string{"hunk-A"}}}
+
+	provider := &fakeProvider{}
+	summaries, err := summarizeChunks(context.Background(), provider, chunks, 0)
+	if err != nil {
+		t.Fatalf("summarizeChunks returned error with parallel=0: %v", err)
+	}
+	if len(summaries) != 1 || len(summaries[0]) != 1 {
+		t.Fatalf("got %v, want one summary for one.go", summaries)
+	}
+}
+
+func TestResolveStrategy(t *testing.T) {
+	smallDiff := "diff --git a/x b/x\n@@ -1,1 +1,1 @@\n-a\n+b\n"
+	bigDiff := strings.Repeat("x", 1000)
+
+	if got := resolveStrategy(strategySingle, bigDiff, 10); got != strategySingle {
+		t.Errorf("explicit strategy should not be overridden, got %q", got)
+	}
+	if got := resolveStrategy(strategyAuto, smallDiff, 1_000_000); got != strategySingle {
+		t.Errorf("auto under budget should pick single, got %q", got)
+	}
+	if got := resolveStrategy(strategyAuto, bigDiff, 10); got != strategyMapReduce {
+		t.Errorf("auto over budget should pick map-reduce, got %q", got)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// CommitType is one entry of the allowed Conventional Commit types, e.g.
+// {Name: "feat", Emoji: "✨", Description: "A new feature"}.
+type CommitType struct {
+	Name        string `yaml:"name" toml:"name"`
+	Emoji       string `yaml:"emoji" toml:"emoji"`
+	Description string `yaml:"description" toml:"description"`
+}
+
+// Config holds every knob that used to be hard-coded into promptTemplate.
+// It is loaded from .ai-commit.yaml/.ai-commit.toml (repo root, then
+// $HOME as a fallback) and can be overridden by environment variables.
+type Config struct {
+	Types                  []CommitType `yaml:"types" toml:"types"`
+	EmojiEnabled           bool         `yaml:"emoji" toml:"emoji"`
+	ScopeRequired          bool         `yaml:"scopeRequired" toml:"scopeRequired"`
+	MaxSubjectLength       int          `yaml:"maxSubjectLength" toml:"maxSubjectLength"`
+	BreakingChangePrefixes []string     `yaml:"breakingChangePrefixes" toml:"breakingChangePrefixes"`
+	IssuePrefixes          []string     `yaml:"issuePrefixes" toml:"issuePrefixes"`
+	IssueRegex             string       `yaml:"issueRegex" toml:"issueRegex"`
+	Language               string       `yaml:"language" toml:"language"`
+	Template               string       `yaml:"template" toml:"template"`
+}
+
+// defaultConfig reproduces the behavior ai-commit shipped with before
+// .ai-commit.yaml existed.
+func defaultConfig() Config {
+	return Config{
+		Types: []CommitType{
+			{Name: "feat", Emoji: "✨", Description: "A new feature"},
+			{Name: "fix", Emoji: "🐛", Description: "A bug fix"},
+			{Name: "docs", Emoji: "📚", Description: "Documentation only changes"},
+			{Name: "style", Emoji: "💎", Description: "Changes that do not affect the meaning of the code"},
+			{Name: "refactor", Emoji: "♻️", Description: "Code change that neither fixes a bug nor adds a feature"},
+			{Name: "perf", Emoji: "⚡️", Description: "Code change that improves performance"},
+			{Name: "test", Emoji: "✅", Description: "Adding or correcting tests"},
+			{Name: "build", Emoji: "📦", Description: "Changes to build system or external dependencies"},
+			{Name: "ci", Emoji: "⚙️", Description: "Changes to CI configuration"},
+			{Name: "chore", Emoji: "🧹", Description: "Other changes not modifying src or test files"},
+			{Name: "revert", Emoji: "⏪", Description: "Reverts a previous commit"},
+		},
+		EmojiEnabled:           true,
+		ScopeRequired:          false,
+		MaxSubjectLength:       72,
+		BreakingChangePrefixes: []string{"BREAKING CHANGE:"},
+		IssuePrefixes:          []string{"#"},
+		IssueRegex:             `#\d+`,
+		Language:               "English",
+		Template:               defaultPromptTemplateSrc,
+	}
+}
+
+// loadConfig builds the effective Config by layering, in increasing
+// priority: built-in defaults, $HOME/.ai-commit.{yaml,toml}, the repo
+// root's .ai-commit.{yaml,toml}, then environment variable overrides.
+func loadConfig() (Config, error) {
+	cfg := defaultConfig()
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if err := mergeConfigFile(&cfg, home); err != nil {
+			return cfg, err
+		}
+	}
+
+	if root, err := gitRoot(); err == nil {
+		if err := mergeConfigFile(&cfg, root); err != nil {
+			return cfg, err
+		}
+	}
+
+	applyEnvOverrides(&cfg)
+
+	return cfg, nil
+}
+
+// mergeConfigFile looks for .ai-commit.yaml then .ai-commit.toml in dir and,
+// if found, decodes it directly on top of cfg so unset fields keep their
+// current values.
+func mergeConfigFile(cfg *Config, dir string) error {
+	yamlPath := filepath.Join(dir, ".ai-commit.yaml")
+	if data, err := os.ReadFile(yamlPath); err == nil {
+		return yaml.Unmarshal(data, cfg)
+	}
+
+	tomlPath := filepath.Join(dir, ".ai-commit.toml")
+	if data, err := os.ReadFile(tomlPath); err == nil {
+		return toml.Unmarshal(data, cfg)
+	}
+
+	return nil
+}
+
+// applyEnvOverrides mirrors git-sv's env-var override convention.
+func applyEnvOverrides(cfg *Config) {
+	if raw := os.Getenv("COMMIT_MESSAGE_TYPES"); raw != "" {
+		var types []CommitType
+		for _, entry := range strings.Split(raw, ",") {
+			parts := strings.SplitN(entry, ":", 3)
+			if len(parts) < 2 {
+				continue
+			}
+			ct := CommitType{Name: strings.TrimSpace(parts[0]), Emoji: strings.TrimSpace(parts[1])}
+			if len(parts) == 3 {
+				ct.Description = strings.TrimSpace(parts[2])
+			}
+			types = append(types, ct)
+		}
+		if len(types) > 0 {
+			cfg.Types = types
+		}
+	}
+
+	if issueKey := os.Getenv("ISSUE_KEY_NAME"); issueKey != "" {
+		cfg.IssuePrefixes = []string{issueKey}
+	}
+
+	if raw := os.Getenv("BREAKING_CHANGE_PREFIXES"); raw != "" {
+		cfg.BreakingChangePrefixes = strings.Split(raw, ",")
+	}
+}
+
+func gitRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// defaultPromptTemplateSrc is the Go text/template used to render the LLM
+// prompt when no `template:` override is set in the config file. It is
+// ai-commit's original hard-coded prompt with the Config knobs filled in,
+// plus lines for breaking-change and issue-reference prefixes.
+const defaultPromptTemplateSrc = `You are an AI assistant specialized in generating concise, single-line Conventional Commit messages from git diffs, written in {{.Language}}.
+Your **sole task** is to produce a commit message.
+The **primary and strongly preferred output** is a single line adhering to this exact format:
+{{if .EmojiEnabled}}<emoji> {{end}}<type>({{if .ScopeRequired}}<scope>{{else}}<scope, optional>{{end}}): <short description>
+
+**Body and Footer (AVOID unless absolutely CRITICAL):**
+*   Only include a body or footer if the changes are exceptionally complex AND a single subject line is **demonstrably insufficient** to convey a **vital aspect** (e.g., a significant breaking change that cannot be summarized or hinted at, or an essential issue link).
+*   **Your default behavior must be to summarize everything into the single subject line.**
+*   If unavoidable, separate the body/footer with blank lines as per the specification.
+*   A breaking change must be flagged in the footer using one of: {{range .BreakingChangePrefixes}}{{.}} {{end}}
+*   Issue references, when present in the diff or hint, should be prefixed with one of: {{range .IssuePrefixes}}{{.}} {{end}}(matching {{.IssueRegex}})
+
+Available types{{if .EmojiEnabled}} and their emojis{{end}} (choose one for the subject line):
+{{range .Types}}- {{.Name}}{{if $.EmojiEnabled}}: {{.Emoji}}{{end}} ({{.Description}})
+{{end}}
+Guidelines for the **single subject line**:
+1.  **Summarize the Core Change**: Identify the primary purpose/goal of the entire diff.
+2.  **Imperative Mood**: Start with a verb (e.g., 'Add', 'Fix', 'Update', 'Refactor').
+3.  **Conciseness**: Aim for a subject no longer than {{.MaxSubjectLength}} characters. Be brief but informative.
+4.  **No Period**: Do not end the subject line with a period.
+5.  **Scope**: {{if .ScopeRequired}}Required — a noun describing the affected area (e.g., 'api', 'ui', 'auth').{{else}}Optional — a noun describing the affected area (e.g., 'api', 'ui', 'auth').{{end}}
+6.  **Type{{if .EmojiEnabled}} & Emoji{{end}}**: Select the most fitting type{{if .EmojiEnabled}} and its emoji{{end}}.
+7.  **Focus**: Prioritize the overall *intent* and *impact*, not granular file-by-file details. Distill the essence of the changes.{{.AdditionalContext}}
+Here is the git diff of the changes:
+\\\ diff
+{{.Diff}}
+\\\ diff
+Based ONLY on the diff provided, generate the commit message.
+**Your response should be ONLY the commit message itself, with NO additional text, explanation, or markdown formatting surrounding it.**
+**Strive for a single line. Every time.**`
+
+// promptData is the value passed to Config.Template when rendering.
+type promptData struct {
+	Config
+	AdditionalContext string
+	Diff              string
+}
+
+// buildPrompt renders cfg.Template (or the built-in default) with the given
+// hint and staged diff.
+func buildPrompt(cfg Config, additionalContext, diff string) (string, error) {
+	tmpl, err := template.New("prompt").Parse(cfg.Template)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, promptData{Config: cfg, AdditionalContext: additionalContext, Diff: diff}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+const geminiModel = "gemini-2.5-flash-preview-05-20"
+
+type geminiProvider struct {
+	client *genai.Client
+	model  string
+}
+
+func newGeminiProvider(ctx context.Context, model string) (Provider, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY environment variable is not set")
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating Gemini client: %w", err)
+	}
+
+	return &geminiProvider{client: client, model: model}, nil
+}
+
+func (p *geminiProvider) Model() string {
+	return p.model
+}
+
+// appendCandidateTexts extracts each candidate's non-empty, not-yet-seen
+// text from result and appends it to dst.
+func appendCandidateTexts(result *genai.GenerateContentResponse, seen map[string]bool, dst []string) []string {
+	for _, c := range result.Candidates {
+		if c.Content == nil || len(c.Content.Parts) == 0 {
+			continue
+		}
+		text := strings.TrimSpace(c.Content.Parts[0].Text)
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		dst = append(dst, text)
+	}
+	return dst
+}
+
+// topUpCandidates issues sequential single-candidate requests (via
+// genFunc, which must respond with CandidateCount: 1) to fill candidates
+// up to count: several Gemini models, particularly the flash tier, ignore
+// CandidateCount and always return a single candidate, so --count would
+// otherwise silently collapse to one suggestion on the default provider.
+func topUpCandidates(ctx context.Context, count int, seen map[string]bool, candidates []string, genFunc func(context.Context) (*genai.GenerateContentResponse, error)) []string {
+	for len(candidates) < count {
+		result, err := genFunc(ctx)
+		if err != nil {
+			break
+		}
+		before := len(candidates)
+		candidates = appendCandidateTexts(result, seen, candidates)
+		if len(candidates) == before {
+			break
+		}
+	}
+	return candidates
+}
+
+func (p *geminiProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	result, err := p.client.Models.GenerateContent(
+		ctx,
+		p.model,
+		genai.Text(prompt),
+		&genai.GenerateContentConfig{
+			CandidateCount: int32(count),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, count)
+	candidates := appendCandidateTexts(result, seen, make([]string, 0, count))
+	candidates = topUpCandidates(ctx, count, seen, candidates, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		return p.client.Models.GenerateContent(ctx, p.model, genai.Text(prompt), &genai.GenerateContentConfig{CandidateCount: 1})
+	})
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return candidates, nil
+}
+
+// commitMessageSchema describes CommitMessage for Gemini's native
+// responseSchema/responseMimeType structured-output mode.
+var commitMessageSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"emoji":          {Type: genai.TypeString},
+		"type":           {Type: genai.TypeString},
+		"scope":          {Type: genai.TypeString},
+		"subject":        {Type: genai.TypeString},
+		"body":           {Type: genai.TypeString},
+		"footer":         {Type: genai.TypeString},
+		"breakingChange": {Type: genai.TypeString},
+		"issueRefs":      {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+	},
+	Required: []string{"type", "subject"},
+}
+
+// GenerateJSON asks Gemini for count responses in one call, topping up with
+// single-candidate calls when fewer come back, each constrained to
+// commitMessageSchema rather than relying purely on prompt instructions.
+func (p *geminiProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	result, err := p.client.Models.GenerateContent(
+		ctx,
+		p.model,
+		genai.Text(prompt),
+		&genai.GenerateContentConfig{
+			CandidateCount:   int32(count),
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   commitMessageSchema,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, count)
+	raws := appendCandidateTexts(result, seen, make([]string, 0, count))
+	raws = topUpCandidates(ctx, count, seen, raws, func(ctx context.Context) (*genai.GenerateContentResponse, error) {
+		return p.client.Models.GenerateContent(ctx, p.model, genai.Text(prompt), &genai.GenerateContentConfig{
+			CandidateCount:   1,
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   commitMessageSchema,
+		})
+	})
+	if len(raws) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return raws, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// sourcesToSkip are the prepare-commit-msg sources where a message is
+// already meaningful (merge/squash, an explicit -m/-F, or a template) or the
+// user is amending, so overwriting the message file would be unwelcome.
+var sourcesToSkip = map[string]bool{
+	"merge":    true,
+	"squash":   true,
+	"commit":   true,
+	"message":  true,
+	"template": true,
+}
+
+// runHook implements `ai-commit --hook`, meant to be invoked as (or from) a
+// git prepare-commit-msg hook: args mirrors the hook's own argv, i.e.
+// args[0] is the commit message file, args[1] is the optional source.
+//
+// Any failure to produce a suggestion (no staged diff, unreachable provider,
+// validation exhausted) is swallowed rather than returned: this is a
+// best-effort assist, and it must never block `git commit` from succeeding
+// with the scaffold git already prepared.
+func runHook(ctx context.Context, provider Provider, cfg Config, args []string, opts genOptions) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: ai-commit --hook <message-file> [source] [sha1]")
+	}
+	msgFile := args[0]
+
+	source := ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+	if sourcesToSkip[source] {
+		return nil
+	}
+
+	existing, err := os.ReadFile(msgFile)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", msgFile, err)
+	}
+
+	promptText, err := buildPromptForStaged(ctx, provider, cfg, "", opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ai-commit: skipping suggestion: %v\n", err)
+		return nil
+	}
+
+	msg, err := generateValidated(ctx, provider, cfg, promptText, opts.maxRetries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ai-commit: skipping suggestion: %v\n", err)
+		return nil
+	}
+
+	content := msg.Format(cfg) + "\n" + string(existing)
+	return os.WriteFile(msgFile, []byte(content), 0644)
+}
+
+// extractChainCall pulls the lines preceding our own `exec ... --hook "$@"`
+// line out of a previously installed wrapper script, so re-running
+// install-hook re-emits whatever backed-up hook it chains to instead of
+// silently dropping it.
+func extractChainCall(script string) string {
+	var chain []string
+	for _, line := range strings.Split(script, "\n") {
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+		if strings.Contains(line, `--hook "$@"`) {
+			break
+		}
+		chain = append(chain, line)
+	}
+	if len(chain) == 0 {
+		return ""
+	}
+	return strings.Join(chain, "\n") + "\n"
+}
+
+// installHook wires the current binary into the current repo's
+// prepare-commit-msg hook and registers a `git ai-commit` alias.
+func installHook() error {
+	root, err := gitRoot()
+	if err != nil {
+		return fmt.Errorf("locating git repo: %w", err)
+	}
+
+	binPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating ai-commit binary: %w", err)
+	}
+
+	hookPath := root + "/.git/hooks/prepare-commit-msg"
+	chainCall := ""
+	if existing, err := os.ReadFile(hookPath); err == nil {
+		if strings.Contains(string(existing), `--hook "$@"`) {
+			// Already our wrapper (e.g. re-running install-hook): keep
+			// whatever chained call precedes it instead of dropping it.
+			chainCall = extractChainCall(string(existing))
+		} else {
+			backupPath := hookPath + ".ai-commit-backup"
+			if err := os.WriteFile(backupPath, existing, 0755); err != nil {
+				return fmt.Errorf("backing up existing hook to %s: %w", backupPath, err)
+			}
+			chainCall = fmt.Sprintf("%q \"$@\" || exit $?\n", backupPath)
+		}
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%sexec %q --hook \"$@\"\n", chainCall, binPath)
+	if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", hookPath, err)
+	}
+
+	aliasCmd := exec.Command("git", "config", "alias.ai-commit", fmt.Sprintf("!%s", binPath))
+	if err := aliasCmd.Run(); err != nil {
+		return fmt.Errorf("registering git alias: %w", err)
+	}
+
+	fmt.Printf("Installed prepare-commit-msg hook at %s and registered `git ai-commit` alias.\n", strings.TrimPrefix(hookPath, root+"/"))
+	if chainCall != "" {
+		fmt.Println("Your previous hook was preserved and is now chained before ai-commit's suggestion.")
+	}
+	return nil
+}
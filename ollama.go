@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const defaultOllamaURL = "http://localhost:11434"
+
+type ollamaProvider struct {
+	baseURL string
+	model   string
+	http    *http.Client
+}
+
+func newOllamaProvider(model string) (Provider, error) {
+	baseURL := os.Getenv("OLLAMA_URL")
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+
+	return &ollamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		http:    &http.Client{},
+	}, nil
+}
+
+func (p *ollamaProvider) Model() string {
+	return p.model
+}
+
+func (p *ollamaProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	return genericGenerateJSON(ctx, p, prompt, count)
+}
+
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+}
+
+// Generate issues count sequential requests against the local Ollama
+// /api/generate endpoint, which has no concept of multiple candidates.
+func (p *ollamaProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	candidates := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		text, err := p.generateOnce(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		if text != "" {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return candidates, nil
+}
+
+func (p *ollamaProvider) generateOnce(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(ollamaGenerateRequest{Model: p.model, Prompt: prompt, Stream: false})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling ollama at %s: %w", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ollama returned status %s", resp.Status)
+	}
+
+	var out ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decoding ollama response: %w", err)
+	}
+	return strings.TrimSpace(out.Response), nil
+}
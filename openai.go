@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type openAIProvider struct {
+	client *openai.Client
+	model  string
+}
+
+func newOpenAIProvider(model string) (Provider, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	}
+
+	return &openAIProvider{
+		client: openai.NewClient(apiKey),
+		model:  model,
+	}, nil
+}
+
+func (p *openAIProvider) Model() string {
+	return p.model
+}
+
+func (p *openAIProvider) GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error) {
+	return genericGenerateJSON(ctx, p, prompt, count)
+}
+
+func (p *openAIProvider) Generate(ctx context.Context, prompt string, count int) ([]string, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: p.model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: prompt},
+		},
+		N: count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0, len(resp.Choices))
+	for _, choice := range resp.Choices {
+		text := strings.TrimSpace(choice.Message.Content)
+		if text != "" {
+			candidates = append(candidates, text)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return candidates, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Provider generates one or more completions from a fully rendered
+// prompt. Implementations wrap a specific LLM backend.
+type Provider interface {
+	// Generate returns count raw completions for prompt, using the
+	// provider's native multi-candidate support where it has one.
+	Generate(ctx context.Context, prompt string, count int) ([]string, error)
+	// GenerateJSON returns count completions for prompt, which already
+	// asks the model to respond with a JSON object.
+	GenerateJSON(ctx context.Context, prompt string, count int) ([]string, error)
+	// Model returns the model identifier in use, shown in the spinner.
+	Model() string
+}
+
+// genericGenerateJSON is the GenerateJSON implementation shared by providers
+// with no native structured-output mode: it relies entirely on the JSON
+// instructions already embedded in prompt by the caller, and reuses
+// Generate's own count handling (native N for OpenAI/Azure, a sequential
+// loop for Anthropic/Ollama).
+func genericGenerateJSON(ctx context.Context, provider Provider, prompt string, count int) ([]string, error) {
+	return provider.Generate(ctx, prompt, count)
+}
+
+const (
+	providerGemini = "gemini"
+	providerOpenAI = "openai"
+	providerAzure  = "azure"
+	providerClaude = "anthropic"
+	providerOllama = "ollama"
+)
+
+// defaultModels holds the per-provider model used when AI_COMMIT_MODEL is
+// not set.
+var defaultModels = map[string]string{
+	providerGemini: geminiModel,
+	providerOpenAI: "gpt-4o-mini",
+	providerAzure:  "gpt-4o-mini",
+	providerClaude: "claude-3-5-sonnet-latest",
+	providerOllama: "llama3",
+}
+
+// newProvider builds the Provider selected by AI_COMMIT_PROVIDER (defaulting
+// to Gemini for backward compatibility), reading its credentials/model from
+// the environment.
+func newProvider(ctx context.Context) (Provider, error) {
+	name := os.Getenv("AI_COMMIT_PROVIDER")
+	if name == "" {
+		name = providerGemini
+	}
+
+	model := os.Getenv("AI_COMMIT_MODEL")
+	if model == "" {
+		model = defaultModels[name]
+	}
+
+	switch name {
+	case providerGemini:
+		return newGeminiProvider(ctx, model)
+	case providerOpenAI:
+		return newOpenAIProvider(model)
+	case providerAzure:
+		return newAzureOpenAIProvider(model)
+	case providerClaude:
+		return newAnthropicProvider(model)
+	case providerOllama:
+		return newOllamaProvider(model)
+	default:
+		return nil, fmt.Errorf("unknown AI_COMMIT_PROVIDER %q (want one of gemini, openai, azure, anthropic, ollama)", name)
+	}
+}
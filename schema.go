@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommitMessage is the structured shape the model is asked to return instead
+// of free-form text, mirroring the fields promptTemplate used to describe in
+// prose.
+type CommitMessage struct {
+	Emoji          string   `json:"emoji,omitempty"`
+	Type           string   `json:"type"`
+	Scope          string   `json:"scope,omitempty"`
+	Subject        string   `json:"subject"`
+	Body           string   `json:"body,omitempty"`
+	Footer         string   `json:"footer,omitempty"`
+	BreakingChange string   `json:"breakingChange,omitempty"`
+	IssueRefs      []string `json:"issueRefs,omitempty"`
+}
+
+// Validate checks m against cfg's allowed types, scope requirement, subject
+// length, and issue reference format.
+func (m CommitMessage) Validate(cfg Config) error {
+	if m.Subject == "" {
+		return fmt.Errorf("subject must not be empty")
+	}
+	if n := utf8.RuneCountInString(m.Subject); n > cfg.MaxSubjectLength {
+		return fmt.Errorf("subject is %d characters, exceeds max of %d", n, cfg.MaxSubjectLength)
+	}
+
+	typeOK := false
+	for _, t := range cfg.Types {
+		if t.Name == m.Type {
+			typeOK = true
+			break
+		}
+	}
+	if !typeOK {
+		return fmt.Errorf("type %q is not one of the allowed types", m.Type)
+	}
+
+	if cfg.ScopeRequired && m.Scope == "" {
+		return fmt.Errorf("scope is required but missing")
+	}
+
+	if cfg.IssueRegex != "" && len(m.IssueRefs) > 0 {
+		re, err := regexp.Compile(cfg.IssueRegex)
+		if err != nil {
+			return fmt.Errorf("invalid issueRegex in config: %w", err)
+		}
+		for _, ref := range m.IssueRefs {
+			if !re.MatchString(ref) {
+				return fmt.Errorf("issue reference %q does not match %s", ref, cfg.IssueRegex)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Format renders m as the final commit message text: a single subject line,
+// followed by an optional body/footer block.
+func (m CommitMessage) Format(cfg Config) string {
+	var subject strings.Builder
+	if cfg.EmojiEnabled && m.Emoji != "" {
+		subject.WriteString(m.Emoji)
+		subject.WriteByte(' ')
+	}
+	subject.WriteString(m.Type)
+	if m.Scope != "" {
+		fmt.Fprintf(&subject, "(%s)", m.Scope)
+	}
+	subject.WriteString(": ")
+	subject.WriteString(m.Subject)
+
+	var out strings.Builder
+	out.WriteString(subject.String())
+
+	if m.Body != "" {
+		out.WriteString("\n\n")
+		out.WriteString(m.Body)
+	}
+
+	footer := m.Footer
+	if m.BreakingChange != "" {
+		prefix := "BREAKING CHANGE:"
+		if len(cfg.BreakingChangePrefixes) > 0 {
+			prefix = cfg.BreakingChangePrefixes[0]
+		}
+		line := fmt.Sprintf("%s %s", prefix, m.BreakingChange)
+		if footer == "" {
+			footer = line
+		} else {
+			footer = line + "\n" + footer
+		}
+	}
+	if footer != "" {
+		out.WriteString("\n\n")
+		out.WriteString(footer)
+	}
+
+	return out.String()
+}
+
+const jsonInstructions = `
+Respond with ONLY a single JSON object (no markdown fences, no surrounding text) matching this shape:
+{"emoji": string, "type": string, "scope": string, "subject": string, "body": string, "footer": string, "breakingChange": string, "issueRefs": [string]}
+All fields except "type" and "subject" are optional and should be omitted (empty string / empty array) when not applicable.`
+
+// plainTextClosers strips the prompt's closing instructions to emit a bare,
+// single-line commit message: structured mode asks for a JSON object
+// instead, and leaving those closers in place contradicts jsonInstructions,
+// which providers without native schema enforcement (unlike Gemini) will
+// often resolve by ignoring the JSON request entirely.
+var plainTextClosers = strings.NewReplacer(
+	"**Your response should be ONLY the commit message itself, with NO additional text, explanation, or markdown formatting surrounding it.**", "",
+	"**Strive for a single line. Every time.**", "",
+)
+
+// structuredPrompt adapts prompt for JSON generation by dropping its
+// plain-text closers before appending jsonInstructions.
+func structuredPrompt(prompt string) string {
+	return strings.TrimSpace(plainTextClosers.Replace(prompt)) + "\n" + jsonInstructions
+}
+
+// parseCommitMessage decodes raw model output into a CommitMessage,
+// tolerating a surrounding ```json fence in case the model adds one anyway.
+func parseCommitMessage(raw string) (CommitMessage, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "```json")
+	raw = strings.TrimPrefix(raw, "```")
+	raw = strings.TrimSuffix(raw, "```")
+	raw = strings.TrimSpace(raw)
+
+	var m CommitMessage
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return CommitMessage{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return m, nil
+}
+
+// generateValidated asks provider for a single structured CommitMessage,
+// retrying up to maxRetries times with the validator's error fed back to the
+// model when the response fails to parse or fails cfg's rules.
+func generateValidated(ctx context.Context, provider Provider, cfg Config, prompt string, maxRetries int) (CommitMessage, error) {
+	currentPrompt := structuredPrompt(prompt)
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		raws, err := provider.GenerateJSON(ctx, currentPrompt, 1)
+		if err != nil {
+			return CommitMessage{}, err
+		}
+
+		msg, err := parseCommitMessage(raws[0])
+		if err == nil {
+			if err = msg.Validate(cfg); err == nil {
+				return msg, nil
+			}
+		}
+
+		lastErr = err
+		currentPrompt = fmt.Sprintf("%s\n\nYour previous output failed: %s\nProduce a corrected JSON object.", structuredPrompt(prompt), err)
+	}
+
+	return CommitMessage{}, fmt.Errorf("structured output failed validation after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// generateStructuredCandidates requests count candidates from provider in a
+// single batched GenerateJSON call (native CandidateCount/N under the hood
+// where the backend supports it), then validates each one, falling back to
+// generateValidated's slower retry-with-corrective-prompt loop only for the
+// individual candidates that fail to parse or validate.
+func generateStructuredCandidates(ctx context.Context, provider Provider, cfg Config, prompt string, count, maxRetries int) ([]CommitMessage, error) {
+	raws, err := provider.GenerateJSON(ctx, structuredPrompt(prompt), count)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]CommitMessage, 0, len(raws))
+	for _, raw := range raws {
+		msg, err := parseCommitMessage(raw)
+		if err == nil {
+			if err = msg.Validate(cfg); err == nil {
+				messages = append(messages, msg)
+				continue
+			}
+		}
+
+		retried, err := generateValidated(ctx, provider, cfg, prompt, maxRetries)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, retried)
+	}
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("model returned no usable candidates")
+	}
+	return messages, nil
+}